@@ -0,0 +1,214 @@
+package hdrhistogram
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Encoding cookies for the HdrHistogram V2 wire format, matching the
+// Java/C reference implementations so that payloads produced here can be
+// read by HistogramLogAnalyzer and other tooling built against them.
+const (
+	v2EncodingCookie           = 0x1c849303
+	v2CompressedEncodingCookie = 0x1c849314
+)
+
+// Marshal encodes h using the HdrHistogram V2 log-encoded binary format:
+// a fixed header (cookie, payload length, normalizing index offset,
+// significant figures, lowest/highest trackable value, and the
+// integer-to-double conversion ratio) followed by the recorded counts as
+// ZigZag-varint run-length-encoded values, the whole thing zlib-compressed
+// and wrapped with the V2 compressed cookie.
+func (h *Histogram) Marshal() ([]byte, error) {
+	payload := h.encodeV2Payload()
+
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, int32(v2CompressedEncodingCookie)); err != nil {
+		return nil, err
+	}
+	lengthOffset := buf.Len()
+	if err := binary.Write(&buf, binary.BigEndian, int32(0)); err != nil {
+		return nil, err
+	}
+
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	out := buf.Bytes()
+	binary.BigEndian.PutUint32(out[lengthOffset:], uint32(len(out)-lengthOffset-4))
+	return out, nil
+}
+
+// encodeV2Payload builds the uncompressed V2 body: the header followed by
+// the RLE-encoded counts, walked in index order via the existing
+// iterator so bucket layout stays consistent with the rest of the
+// package.
+func (h *Histogram) encodeV2Payload() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, int32(v2EncodingCookie))
+	lengthOffset := buf.Len()
+	binary.Write(&buf, binary.BigEndian, int32(0)) // payload length, patched below
+	binary.Write(&buf, binary.BigEndian, int32(0)) // normalizing index offset; this package never shifts the zero index
+	binary.Write(&buf, binary.BigEndian, int32(h.significantFigures))
+	binary.Write(&buf, binary.BigEndian, h.lowestTrackableValue)
+	binary.Write(&buf, binary.BigEndian, h.highestTrackableValue)
+	binary.Write(&buf, binary.BigEndian, float64(1.0)) // integerToDoubleConversionRatio; this package only records integers
+
+	countsOffset := buf.Len()
+
+	var zeroRun int64
+	i := h.iterator()
+	for i.next() {
+		if i.countAtIdx == 0 {
+			zeroRun++
+			continue
+		}
+		if zeroRun != 0 {
+			writeZigZagVarint(&buf, -zeroRun)
+			zeroRun = 0
+		}
+		writeZigZagVarint(&buf, i.countAtIdx)
+	}
+
+	out := buf.Bytes()
+	binary.BigEndian.PutUint32(out[lengthOffset:], uint32(len(out)-countsOffset))
+	return out
+}
+
+// Unmarshal decodes a histogram previously produced by Marshal (or by a
+// compatible HdrHistogram V2 writer in another language), recording each
+// decoded run through RecordValues so the result's internal layout
+// matches this process's bucket parameters even if the origin's differed
+// slightly.
+func Unmarshal(data []byte) (*Histogram, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("hdrhistogram: payload too short to contain a header")
+	}
+
+	cookie := int32(binary.BigEndian.Uint32(data[0:4]))
+	body := data[8:]
+
+	switch cookie {
+	case v2CompressedEncodingCookie:
+		zr, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		decompressed, err := io.ReadAll(zr)
+		if err != nil {
+			return nil, err
+		}
+		return decodeV2Payload(decompressed)
+	case v2EncodingCookie:
+		return decodeV2Payload(data)
+	default:
+		return nil, fmt.Errorf("hdrhistogram: unrecognized encoding cookie 0x%x", cookie)
+	}
+}
+
+func decodeV2Payload(data []byte) (*Histogram, error) {
+	r := bytes.NewReader(data[4:]) // skip the cookie; the caller already matched on it
+
+	var payloadLength, normalizingOffset, sigfigs int32
+	var lowest, highest int64
+	var ratio float64
+	for _, field := range []interface{}{&payloadLength, &normalizingOffset, &sigfigs, &lowest, &highest, &ratio} {
+		if err := binary.Read(r, binary.BigEndian, field); err != nil {
+			return nil, fmt.Errorf("hdrhistogram: reading header: %w", err)
+		}
+	}
+
+	h := New(lowest, highest, int(sigfigs))
+
+	// values walks the same index order encodeV2Payload's h.iterator()
+	// used to write the runs, so each decoded run can be mapped back to
+	// the value it represents without depending on a total count (which
+	// a histogram being decoded into doesn't have yet).
+	values := h.valuesInIndexOrder()
+	pos := 0
+
+	for {
+		count, err := readZigZagVarint(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("hdrhistogram: reading counts: %w", err)
+		}
+
+		if count < 0 {
+			pos += int(-count)
+			continue
+		}
+
+		if pos >= len(values) {
+			return nil, fmt.Errorf("hdrhistogram: corrupt payload: too many counts for histogram")
+		}
+		if count > 0 {
+			if err := h.RecordValues(values[pos], count); err != nil {
+				return nil, err
+			}
+		}
+		pos++
+	}
+
+	return h, nil
+}
+
+// valuesInIndexOrder returns the representative value of every counts
+// slot in the same index order the countsIndex/iterator machinery uses,
+// independent of totalCount. It lets decode map a position in the
+// RLE-encoded stream back to a value without needing a populated
+// histogram to iterate over.
+func (h *Histogram) valuesInIndexOrder() []int64 {
+	values := make([]int64, 0, h.countsLen)
+	for bucketIdx := int32(0); bucketIdx < h.bucketCount; bucketIdx++ {
+		subBucketIdx := int32(0)
+		if bucketIdx != 0 {
+			subBucketIdx = h.subBucketHalfCount
+		}
+		for ; subBucketIdx < h.subBucketCount; subBucketIdx++ {
+			values = append(values, h.valueFromIndex(bucketIdx, subBucketIdx))
+		}
+	}
+	return values
+}
+
+// writeZigZagVarint appends v to buf as a ZigZag-encoded base-128 varint,
+// the encoding the HdrHistogram V2 format uses for counts so that small
+// runs of zeros (encoded as negative values) cost as few bytes as small
+// positive counts.
+func writeZigZagVarint(buf *bytes.Buffer, v int64) {
+	zz := uint64(v<<1) ^ uint64(v>>63)
+	for zz >= 0x80 {
+		buf.WriteByte(byte(zz) | 0x80)
+		zz >>= 7
+	}
+	buf.WriteByte(byte(zz))
+}
+
+func readZigZagVarint(r *bytes.Reader) (int64, error) {
+	var zz uint64
+	var shift uint
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		zz |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			break
+		}
+		shift += 7
+	}
+	return int64(zz>>1) ^ -int64(zz&1), nil
+}
@@ -0,0 +1,84 @@
+package hdrhistogram
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarshalUnmarshalRoundTrip(t *testing.T) {
+	h := New(1, 3600000000, 3)
+	for i := int64(1); i <= 1000; i++ {
+		if err := h.RecordValue(i * 137); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := h.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := base64.StdEncoding.EncodeToString(data)[:8]; got != "HISTFAAA" {
+		t.Fatalf("wrong outer cookie: got %q, want HISTFAAA...", got)
+	}
+
+	h2, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h2.totalCount != h.totalCount {
+		t.Fatalf("totalCount mismatch: got %d want %d", h2.totalCount, h.totalCount)
+	}
+	for _, q := range []float64{50, 90, 99, 99.9} {
+		if got, want := h2.ValueAtQuantile(q), h.ValueAtQuantile(q); got != want {
+			t.Fatalf("quantile %v mismatch: got %d want %d", q, got, want)
+		}
+	}
+}
+
+func TestLogWriterReader(t *testing.T) {
+	var buf bytes.Buffer
+	base := time.Unix(1000, 0)
+	lw := NewLogWriter(&buf, base)
+
+	h := New(1, 3600000000, 3)
+	h.RecordValue(42)
+	h.RecordValue(4200)
+
+	if err := lw.WriteInterval("t1", base, time.Second, h); err != nil {
+		t.Fatal(err)
+	}
+
+	lr := NewLogReader(&buf)
+	iv, err := lr.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iv.Tag != "t1" {
+		t.Fatalf("tag mismatch: %q", iv.Tag)
+	}
+	if iv.Histogram.totalCount != 2 {
+		t.Fatalf("totalCount mismatch: %d", iv.Histogram.totalCount)
+	}
+
+	if _, err := lr.Next(); err == nil {
+		t.Fatal("expected io.EOF, got nil")
+	}
+}
+
+func TestLogWriterWritesBaseTimeOnce(t *testing.T) {
+	var buf bytes.Buffer
+	lw := NewLogWriter(&buf, time.Unix(0, 0))
+	h := New(1, 3600000000, 3)
+
+	lw.WriteInterval("", time.Unix(0, 0), time.Second, h)
+	lw.WriteInterval("", time.Unix(1, 0), time.Second, h)
+
+	if n := strings.Count(buf.String(), "#[BaseTime:"); n != 1 {
+		t.Fatalf("expected exactly one BaseTime comment, got %d", n)
+	}
+}
@@ -0,0 +1,167 @@
+// Package exporter converts hdrhistogram.Histogram snapshots into
+// Prometheus native (a.k.a. sparse exponential) histograms, as described
+// by the OpenMetrics native-histogram specification.
+package exporter
+
+import (
+	"math"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/millken/hdrhistogram"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// schemaFor picks the native-histogram schema (the base-2 exponent used
+// for the exponential bucket boundaries) that matches the relative error
+// significantFigures gives an HdrHistogram, so that for 1 or 2
+// significant figures the exported buckets are no coarser than the
+// source histogram already was. Schemas are clamped to the range the
+// OpenMetrics spec allows; from 3 significant figures
+// up the clamp to schema 8 makes the exported buckets coarser than the
+// source histogram's.
+func schemaFor(significantFigures int) int32 {
+	relError := math.Pow(10, -float64(significantFigures))
+	schema := int32(math.Ceil(math.Log2(1 / relError)))
+	if schema > 8 {
+		schema = 8
+	}
+	if schema < -4 {
+		schema = -4
+	}
+	return schema
+}
+
+// ToNativeHistogram converts a snapshot of h into a Prometheus native
+// histogram. significantFigures should match the precision h was created
+// with and determines the exported schema. Each populated HDR bucket is
+// mapped to the exponential bucket ceil(log2(value) * 2^schema), whose
+// upper bound is inclusive, and counts that collide into the same
+// exponential bucket are summed.
+func ToNativeHistogram(h *hdrhistogram.Histogram, significantFigures int) *dto.Histogram {
+	schema := schemaFor(significantFigures)
+	base := math.Pow(2, math.Pow(2, -float64(schema)))
+
+	buckets := make(map[int32]int64)
+	var count, zeroCount uint64
+	var sum float64
+
+	for _, rv := range h.RecordedValues() {
+		if rv.Value <= 0 {
+			zeroCount += uint64(rv.Count)
+			count += uint64(rv.Count)
+			continue
+		}
+		idx := int32(math.Ceil(math.Log(float64(rv.Value)) / math.Log(base)))
+		buckets[idx] += rv.Count
+		count += uint64(rv.Count)
+		sum += float64(rv.Value) * float64(rv.Count)
+	}
+
+	spans, deltas := deltaEncode(buckets)
+
+	return &dto.Histogram{
+		SampleCount:   proto.Uint64(count),
+		SampleSum:     proto.Float64(sum),
+		Schema:        proto.Int32(schema),
+		ZeroThreshold: proto.Float64(0),
+		ZeroCount:     proto.Uint64(zeroCount),
+		PositiveSpan:  spans,
+		PositiveDelta: deltas,
+	}
+}
+
+// deltaEncode turns a sparse index->count map into the span/delta form
+// native histograms use on the wire: contiguous runs of populated
+// buckets become one BucketSpan, and each bucket's count is stored as
+// the delta from the previous populated bucket's count.
+func deltaEncode(buckets map[int32]int64) ([]*dto.BucketSpan, []int64) {
+	if len(buckets) == 0 {
+		return nil, nil
+	}
+
+	indexes := make([]int32, 0, len(buckets))
+	for idx := range buckets {
+		indexes = append(indexes, idx)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	var spans []*dto.BucketSpan
+	deltas := make([]int64, 0, len(indexes))
+
+	var lastIdx int32
+	var lastCount int64
+	for i, idx := range indexes {
+		count := buckets[idx]
+
+		switch {
+		case i == 0:
+			spans = append(spans, &dto.BucketSpan{Offset: proto.Int32(idx), Length: proto.Uint32(1)})
+			deltas = append(deltas, count)
+		case idx == lastIdx+1:
+			span := spans[len(spans)-1]
+			span.Length = proto.Uint32(span.GetLength() + 1)
+			deltas = append(deltas, count-lastCount)
+		default:
+			spans = append(spans, &dto.BucketSpan{Offset: proto.Int32(idx - lastIdx - 1), Length: proto.Uint32(1)})
+			deltas = append(deltas, count-lastCount)
+		}
+
+		lastIdx = idx
+		lastCount = count
+	}
+
+	return spans, deltas
+}
+
+// A Collector adapts an *hdrhistogram.Histogram to the
+// prometheus.Collector interface so it can be registered directly.
+// snapshot is called on every scrape and should return a stable
+// snapshot of the histogram to export, e.g. Recorder.Sample.
+type Collector struct {
+	desc               *prometheus.Desc
+	significantFigures int
+	snapshot           func() *hdrhistogram.Histogram
+}
+
+// NewCollector returns a Collector that, on every scrape, takes a
+// snapshot via snapshot and exports it as a Prometheus native histogram
+// under name/help.
+func NewCollector(name, help string, significantFigures int, snapshot func() *hdrhistogram.Histogram) *Collector {
+	return &Collector{
+		desc:               prometheus.NewDesc(name, help, nil, nil),
+		significantFigures: significantFigures,
+		snapshot:           snapshot,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	h := c.snapshot()
+	ch <- &nativeHistogramMetric{desc: c.desc, histogram: ToNativeHistogram(h, c.significantFigures)}
+}
+
+// nativeHistogramMetric implements prometheus.Metric by writing a
+// pre-built native dto.Histogram straight onto the wire, since
+// client_golang's const-metric constructors don't yet expose the native
+// histogram fields.
+type nativeHistogramMetric struct {
+	desc      *prometheus.Desc
+	histogram *dto.Histogram
+}
+
+func (m *nativeHistogramMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+func (m *nativeHistogramMetric) Write(out *dto.Metric) error {
+	out.Histogram = m.histogram
+	return nil
+}
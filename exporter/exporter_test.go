@@ -0,0 +1,39 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/millken/hdrhistogram"
+)
+
+func TestToNativeHistogram(t *testing.T) {
+	h := hdrhistogram.New(1, 3600000000, 3)
+	for i := int64(1); i <= 1000; i++ {
+		h.RecordValue(i * 97)
+	}
+
+	dh := ToNativeHistogram(h, 3)
+	if dh.GetSampleCount() != 1000 {
+		t.Fatalf("sample count mismatch: %d", dh.GetSampleCount())
+	}
+	if len(dh.GetPositiveSpan()) == 0 {
+		t.Fatalf("expected spans")
+	}
+}
+
+func TestToNativeHistogramZeroValue(t *testing.T) {
+	h := hdrhistogram.New(1, 3600000000, 3)
+	h.RecordValue(0)
+	h.RecordValue(100)
+
+	dh := ToNativeHistogram(h, 3)
+	if dh.GetZeroCount() != 1 {
+		t.Fatalf("expected zero-bucket count 1, got %d", dh.GetZeroCount())
+	}
+	const int32Min = -(1 << 31)
+	for _, span := range dh.GetPositiveSpan() {
+		if span.GetOffset() == int32Min || span.GetOffset() == int32Min+1 {
+			t.Fatalf("bucket offset looks like an overflowed math.Log(0), got corrupted span: %+v", span)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package hdrhistogram
+
+import "fmt"
+
+// float64TrackableRange bounds the internal integer histogram backing a
+// Float64Histogram; values are tracked as integer multiples of an
+// auto-adjusted conversion ratio.
+const float64TrackableRange = 1e12
+
+// A Float64Histogram records float64 values by tracking them in an
+// internal integer Histogram through an automatically maintained
+// integer-to-double conversion ratio. The first recorded value centers
+// the ratio so it sits in the middle of the trackable range; if a later
+// value would fall outside that range, the ratio is shifted in whole
+// power-of-two steps and every previously recorded value is re-recorded
+// against the new ratio.
+type Float64Histogram struct {
+	sigfigs int
+	ratio   float64
+	h       *Histogram
+	dropped int64
+}
+
+// NewFloat64 returns a Float64Histogram able to record float64 values
+// with the given amount of precision, as with New's sigfigs parameter,
+// auto-adjusting its internal range as values are recorded.
+func NewFloat64(sigfigs int) *Float64Histogram {
+	f := &Float64Histogram{sigfigs: sigfigs}
+	f.reinit(1)
+	return f
+}
+
+func (f *Float64Histogram) reinit(ratio float64) {
+	f.ratio = ratio
+	f.h = New(1, int64(float64TrackableRange), f.sigfigs)
+}
+
+// RecordValue records v, shifting the internal conversion ratio first if
+// v would otherwise fall outside the trackable range.
+func (f *Float64Histogram) RecordValue(v float64) error {
+	return f.RecordValues(v, 1)
+}
+
+// RecordValues records n occurrences of v, shifting the internal
+// conversion ratio first if v would otherwise fall outside the trackable
+// range.
+func (f *Float64Histogram) RecordValues(v float64, n int64) error {
+	if v < 0 {
+		return fmt.Errorf("value %f cannot be negative", v)
+	}
+
+	if f.h.totalCount == 0 {
+		// Center the first value in the trackable range so later values
+		// on either side of it have headroom before a shift is needed.
+		f.reinit(v / (float64TrackableRange / 2))
+		if f.ratio == 0 {
+			f.ratio = 1
+		}
+	}
+
+	// A positive value that would round below the lowest trackable value
+	// is out of range too, and needs the ratio to shrink.
+	if iv := f.integerValue(v); v == 0 || iv >= f.h.lowestTrackableValue {
+		if err := f.h.RecordValues(iv, n); err == nil {
+			return nil
+		}
+	}
+
+	f.shiftToCover(v)
+	return f.h.RecordValues(f.integerValue(v), n)
+}
+
+func (f *Float64Histogram) integerValue(v float64) int64 {
+	return int64(v/f.ratio + 0.5)
+}
+
+// shiftToCover doubles or halves the conversion ratio in whole
+// power-of-two steps until v is trackable, re-recording every previously
+// recorded value against the new ratio. Values that no longer fit under
+// the new ratio are counted in Dropped, the same way Merge reports
+// values it had to drop.
+func (f *Float64Histogram) shiftToCover(v float64) {
+	old := f.h
+	oldRatio := f.ratio
+
+	// Compare in float space: v/newRatio can vastly exceed int64's range
+	// before newRatio has grown enough, and converting that early would
+	// overflow and stop the loop short.
+	newRatio := oldRatio
+	for v/newRatio >= float64(old.highestTrackableValue) {
+		newRatio *= 2
+	}
+	for v > 0 && v/newRatio < 1 {
+		newRatio /= 2
+	}
+
+	f.reinit(newRatio)
+
+	i := old.iterator()
+	for i.next() {
+		if i.countAtIdx == 0 {
+			continue
+		}
+		reconstructed := float64(i.valueFromIdx) * oldRatio
+		if err := f.h.RecordValues(f.integerValue(reconstructed), i.countAtIdx); err != nil {
+			f.dropped += i.countAtIdx
+		}
+	}
+}
+
+// Dropped returns the cumulative number of previously recorded values
+// that could not be carried over whenever the internal conversion ratio
+// has had to shift to fit a new value.
+func (f *Float64Histogram) Dropped() int64 {
+	return f.dropped
+}
+
+// ValueAtQuantile returns the recorded value at the given quantile
+// (0..100).
+func (f *Float64Histogram) ValueAtQuantile(q float64) float64 {
+	return float64(f.h.ValueAtQuantile(q)) * f.ratio
+}
+
+// Mean returns the approximate arithmetic mean of the recorded values.
+func (f *Float64Histogram) Mean() float64 {
+	return f.h.Mean() * f.ratio
+}
+
+// StdDev returns the approximate standard deviation of the recorded
+// values.
+func (f *Float64Histogram) StdDev() float64 {
+	return f.h.StdDev() * f.ratio
+}
+
+// Min returns the approximate minimum recorded value.
+func (f *Float64Histogram) Min() float64 {
+	return float64(f.h.Min()) * f.ratio
+}
+
+// Max returns the approximate maximum recorded value.
+func (f *Float64Histogram) Max() float64 {
+	return float64(f.h.Max()) * f.ratio
+}
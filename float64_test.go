@@ -0,0 +1,58 @@
+package hdrhistogram
+
+import "testing"
+
+func TestFloat64HistogramRecordValue(t *testing.T) {
+	f := NewFloat64(3)
+	for _, v := range []float64{0.001, 0.5, 1.2, 1000.0, 1e6} {
+		if err := f.RecordValue(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if f.Max() < 900000 {
+		t.Fatalf("max too small: %v", f.Max())
+	}
+	if f.Min() > 0.01 {
+		t.Fatalf("min too large: %v", f.Min())
+	}
+}
+
+func TestFloat64HistogramShiftDoesNotOverflow(t *testing.T) {
+	f := NewFloat64(3)
+	if err := f.RecordValue(1.0); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RecordValue(1e30); err != nil {
+		t.Fatalf("recording a value far outside the initial range should shift, not error: %v", err)
+	}
+
+	if f.Max() < 1e29 {
+		t.Fatalf("max too small after shift: %v", f.Max())
+	}
+	if f.Dropped() != 0 {
+		t.Fatalf("expected no drops for a two-value histogram, got %d", f.Dropped())
+	}
+}
+
+func TestFloat64HistogramDroppedIsCumulative(t *testing.T) {
+	f := NewFloat64(3)
+	if err := f.RecordValue(1.0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Force the ratio to shrink so the first value's equivalent bucket no
+	// longer fits, dropping it; repeat so a second shift adds to the same
+	// total instead of resetting it.
+	if err := f.RecordValue(1e-13); err != nil {
+		t.Fatal(err)
+	}
+	firstShiftDropped := f.Dropped()
+
+	if err := f.RecordValue(1e-26); err != nil {
+		t.Fatal(err)
+	}
+	if f.Dropped() < firstShiftDropped {
+		t.Fatalf("expected Dropped to accumulate across shifts, got %d after first shift and %d after second", firstShiftDropped, f.Dropped())
+	}
+}
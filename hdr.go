@@ -257,6 +257,28 @@ func (h *Histogram) CumulativeDistribution() []Bracket {
 	return result
 }
 
+// A RecordedValue pairs a recorded value with the number of times it (or
+// any value equivalent to it) was recorded.
+type RecordedValue struct {
+	Value int64
+	Count int64
+}
+
+// RecordedValues returns, in increasing order, every populated bucket's
+// representative value (its highest equivalent value) and count. It lets
+// callers outside this package, such as exporters, walk a snapshot
+// without reaching into the implementation.
+func (h *Histogram) RecordedValues() []RecordedValue {
+	var result []RecordedValue
+	i := h.iterator()
+	for i.next() {
+		if i.countAtIdx != 0 {
+			result = append(result, RecordedValue{Value: i.highestEquivalentValue, Count: i.countAtIdx})
+		}
+	}
+	return result
+}
+
 func (h *Histogram) iterator() *iterator {
 	return &iterator{
 		h:            h,
@@ -0,0 +1,137 @@
+package hdrhistogram
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogWriter writes histograms to an HdrHistogram interval log: comment
+// lines, a "#[BaseTime: ...]" line, and one
+// "[Tag=tag,]startTime,length,max,payload" line per interval, with
+// payload being a base64-encoded Marshal output.
+type LogWriter struct {
+	w         io.Writer
+	baseTime  time.Time
+	wroteBase bool
+}
+
+// NewLogWriter returns a LogWriter that timestamps intervals relative to
+// baseTime.
+func NewLogWriter(w io.Writer, baseTime time.Time) *LogWriter {
+	return &LogWriter{w: w, baseTime: baseTime}
+}
+
+// WriteComment writes a '#'-prefixed comment line.
+func (lw *LogWriter) WriteComment(comment string) error {
+	_, err := fmt.Fprintf(lw.w, "#%s\n", comment)
+	return err
+}
+
+// WriteInterval appends one interval to the log: h as recorded between
+// startTime and startTime+duration, optionally under tag. The base time
+// header is written automatically before the first interval.
+func (lw *LogWriter) WriteInterval(tag string, startTime time.Time, duration time.Duration, h *Histogram) error {
+	if !lw.wroteBase {
+		if err := lw.WriteComment(fmt.Sprintf("[BaseTime: %.3f]", float64(lw.baseTime.Unix()))); err != nil {
+			return err
+		}
+		lw.wroteBase = true
+	}
+
+	data, err := h.Marshal()
+	if err != nil {
+		return err
+	}
+	payload := base64.StdEncoding.EncodeToString(data)
+
+	startSec := startTime.Sub(lw.baseTime).Seconds()
+	lengthSec := duration.Seconds()
+	maxValue := float64(h.Max())
+
+	if tag != "" {
+		_, err = fmt.Fprintf(lw.w, "Tag=%s,%.3f,%.3f,%.3f,%s\n", tag, startSec, lengthSec, maxValue, payload)
+	} else {
+		_, err = fmt.Fprintf(lw.w, "%.3f,%.3f,%.3f,%s\n", startSec, lengthSec, maxValue, payload)
+	}
+	return err
+}
+
+// A LogInterval is one histogram entry read back from an interval log.
+type LogInterval struct {
+	Tag       string
+	StartTime float64
+	Length    float64
+	Max       float64
+	Histogram *Histogram
+}
+
+// LogReader reads histograms back from an HdrHistogram interval log
+// written by LogWriter, or by the Java/C reference implementations.
+type LogReader struct {
+	s *bufio.Scanner
+}
+
+// NewLogReader returns a LogReader reading from r.
+func NewLogReader(r io.Reader) *LogReader {
+	return &LogReader{s: bufio.NewScanner(r)}
+}
+
+// Next reads and decodes the next interval, returning io.EOF once the log
+// is exhausted. Comment lines, including the base time header, are
+// skipped.
+func (lr *LogReader) Next() (*LogInterval, error) {
+	for lr.s.Scan() {
+		line := strings.TrimSpace(lr.s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var tag string
+		if strings.HasPrefix(line, "Tag=") {
+			idx := strings.IndexByte(line, ',')
+			if idx < 0 {
+				return nil, fmt.Errorf("hdrhistogram: malformed log line %q", line)
+			}
+			tag = line[len("Tag="):idx]
+			line = line[idx+1:]
+		}
+
+		fields := strings.SplitN(line, ",", 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("hdrhistogram: malformed log line %q", line)
+		}
+
+		start, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("hdrhistogram: parsing start time: %w", err)
+		}
+		length, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("hdrhistogram: parsing interval length: %w", err)
+		}
+		max, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("hdrhistogram: parsing max value: %w", err)
+		}
+
+		data, err := base64.StdEncoding.DecodeString(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("hdrhistogram: decoding payload: %w", err)
+		}
+		h, err := Unmarshal(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return &LogInterval{Tag: tag, StartTime: start, Length: length, Max: max, Histogram: h}, nil
+	}
+	if err := lr.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,164 @@
+package hdrhistogram
+
+import (
+	"fmt"
+	"math"
+)
+
+// A LogLinearHistogram is a Histogram-compatible data structure that uses
+// base-10 log-linear bucketing instead of HDR's power-of-two sub-buckets:
+// each decade [10^exp, 10^(exp+1)) is split into binsPerDecade
+// linearly-spaced bins. It remains mergeable across processes that agree
+// on (minPower, maxPower, binsPerDecade).
+type LogLinearHistogram struct {
+	minPower, maxPower int
+	binsPerDecade      int
+	counts             []int64
+	totalCount         int64
+}
+
+// NewLogLinear returns a LogLinearHistogram covering values from
+// 10^minPower (inclusive) to 10^maxPower (exclusive), with binsPerDecade
+// linearly-spaced bins in each decade.
+func NewLogLinear(minPower, maxPower, binsPerDecade int) *LogLinearHistogram {
+	if maxPower <= minPower {
+		panic(fmt.Errorf("maxPower must be > minPower (was %d, %d)", maxPower, minPower))
+	}
+	if binsPerDecade < 1 {
+		panic(fmt.Errorf("binsPerDecade must be >= 1 (was %d)", binsPerDecade))
+	}
+
+	return &LogLinearHistogram{
+		minPower:      minPower,
+		maxPower:      maxPower,
+		binsPerDecade: binsPerDecade,
+		counts:        make([]int64, (maxPower-minPower)*binsPerDecade),
+	}
+}
+
+// RecordValue records the given value, returning an error if the value is
+// out of range.
+func (l *LogLinearHistogram) RecordValue(v float64) error {
+	return l.RecordValues(v, 1)
+}
+
+// RecordValues records n occurrences of the given value, returning an
+// error if the value is out of range.
+func (l *LogLinearHistogram) RecordValues(v float64, n int64) error {
+	idx, err := l.indexFor(v)
+	if err != nil {
+		return err
+	}
+	l.counts[idx] += n
+	l.totalCount += n
+	return nil
+}
+
+// indexFor decomposes v as mantissa * 10^exp with 1 <= mantissa < 10 and
+// maps it onto the bin covering it.
+func (l *LogLinearHistogram) indexFor(v float64) (int, error) {
+	if v <= 0 {
+		return 0, fmt.Errorf("value %g must be positive", v)
+	}
+
+	exp := int(math.Floor(math.Log10(v)))
+	mantissa := v / math.Pow(10, float64(exp))
+	if mantissa < 1 {
+		mantissa = 1
+	} else if mantissa >= 10 {
+		mantissa /= 10
+		exp++
+	}
+
+	if exp < l.minPower || exp >= l.maxPower {
+		return 0, fmt.Errorf("value %g is outside [10^%d, 10^%d)", v, l.minPower, l.maxPower)
+	}
+
+	bin := int((mantissa - 1) * float64(l.binsPerDecade) / 9)
+	if bin >= l.binsPerDecade {
+		bin = l.binsPerDecade - 1
+	}
+
+	return (exp-l.minPower)*l.binsPerDecade + bin, nil
+}
+
+// lowestEquivalentValue returns the lower (inclusive) bound of the bin at
+// idx.
+func (l *LogLinearHistogram) lowestEquivalentValue(idx int) float64 {
+	exp, bin := l.minPower+idx/l.binsPerDecade, idx%l.binsPerDecade
+	return math.Pow(10, float64(exp)) * (1 + 9*float64(bin)/float64(l.binsPerDecade))
+}
+
+// highestEquivalentValue returns the upper (exclusive) bound of the bin
+// at idx.
+func (l *LogLinearHistogram) highestEquivalentValue(idx int) float64 {
+	exp, bin := l.minPower+idx/l.binsPerDecade, idx%l.binsPerDecade
+	return math.Pow(10, float64(exp)) * (1 + 9*float64(bin+1)/float64(l.binsPerDecade))
+}
+
+// medianEquivalentValue returns the midpoint of the bin at idx.
+func (l *LogLinearHistogram) medianEquivalentValue(idx int) float64 {
+	return (l.lowestEquivalentValue(idx) + l.highestEquivalentValue(idx)) / 2
+}
+
+// ValueAtQuantile returns the recorded value at the given quantile
+// (0..100).
+func (l *LogLinearHistogram) ValueAtQuantile(q float64) float64 {
+	if q > 100 {
+		q = 100
+	}
+
+	countAtPercentile := int64(((q / 100) * float64(l.totalCount)) + 0.5)
+
+	var total int64
+	for idx, c := range l.counts {
+		if c == 0 {
+			continue
+		}
+		total += c
+		if total >= countAtPercentile {
+			return l.highestEquivalentValue(idx)
+		}
+	}
+	return 0
+}
+
+// Merge merges the data stored in the given histogram with the receiver,
+// returning the number of recorded values which had to be dropped. Both
+// histograms must agree on (minPower, maxPower, binsPerDecade); if they
+// don't, every value in from is counted as dropped.
+func (l *LogLinearHistogram) Merge(from *LogLinearHistogram) (dropped int64) {
+	if l.minPower != from.minPower || l.maxPower != from.maxPower || l.binsPerDecade != from.binsPerDecade {
+		return from.totalCount
+	}
+
+	for idx, c := range from.counts {
+		if c == 0 {
+			continue
+		}
+		if err := l.RecordValues(from.medianEquivalentValue(idx), c); err != nil {
+			dropped += c
+		}
+	}
+	return
+}
+
+// CumulativeDistribution returns an ordered list of brackets of the
+// distribution of recorded values.
+func (l *LogLinearHistogram) CumulativeDistribution() []Bracket {
+	var result []Bracket
+
+	var total int64
+	for _, c := range l.counts {
+		if c == 0 {
+			continue
+		}
+		total += c
+		result = append(result, Bracket{
+			Quantile: 100 * float64(total) / float64(l.totalCount),
+			Count:    total,
+		})
+	}
+
+	return result
+}
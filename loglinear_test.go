@@ -0,0 +1,51 @@
+package hdrhistogram
+
+import "testing"
+
+func TestLogLinearHistogramRecordValue(t *testing.T) {
+	l := NewLogLinear(-9, 9, 90)
+	for _, v := range []float64{1e-9, 1e-6, 1, 1000, 1e8} {
+		if err := l.RecordValue(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if q := l.ValueAtQuantile(50); q <= 0 {
+		t.Fatalf("expected a positive quantile, got %v", q)
+	}
+}
+
+func TestLogLinearHistogramOutOfRange(t *testing.T) {
+	l := NewLogLinear(-9, 9, 90)
+	if err := l.RecordValue(1e20); err == nil {
+		t.Fatal("expected an error for a value outside [10^minPower, 10^maxPower)")
+	}
+}
+
+func TestLogLinearHistogramMerge(t *testing.T) {
+	a := NewLogLinear(-9, 9, 90)
+	a.RecordValue(5)
+
+	b := NewLogLinear(-9, 9, 90)
+	b.RecordValue(50)
+	b.RecordValue(500)
+
+	if dropped := a.Merge(b); dropped != 0 {
+		t.Fatalf("unexpected drop: %d", dropped)
+	}
+
+	cd := a.CumulativeDistribution()
+	if len(cd) == 0 || cd[len(cd)-1].Count != 3 {
+		t.Fatalf("expected cumulative total of 3, got %+v", cd)
+	}
+}
+
+func TestLogLinearHistogramMergeMismatchedParams(t *testing.T) {
+	a := NewLogLinear(-9, 9, 90)
+	b := NewLogLinear(-5, 5, 45)
+	b.RecordValue(5)
+
+	if dropped := a.Merge(b); dropped != 1 {
+		t.Fatalf("expected all of from's values to be dropped, got %d", dropped)
+	}
+}
@@ -0,0 +1,77 @@
+package hdrhistogram
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// A WriterReaderPhaser lets many writer goroutines enter and exit a
+// critical section concurrently while a single reader waits for every
+// writer that was in flight at a given moment to drain, without
+// requiring writers to take a lock on the fast path.
+type WriterReaderPhaser struct {
+	startEpoch   int64
+	evenEndEpoch int64
+	oddEndEpoch  int64
+	readerMu     sync.Mutex
+}
+
+// NewWriterReaderPhaser returns a ready-to-use WriterReaderPhaser.
+func NewWriterReaderPhaser() *WriterReaderPhaser {
+	return &WriterReaderPhaser{oddEndEpoch: math.MinInt64}
+}
+
+// WriterCriticalSectionEnter must be called by a writer before it starts
+// mutating the data the phaser guards. The returned value must later be
+// passed to WriterCriticalSectionExit.
+func (p *WriterReaderPhaser) WriterCriticalSectionEnter() int64 {
+	return atomic.AddInt64(&p.startEpoch, 1) - 1
+}
+
+// WriterCriticalSectionExit must be called exactly once by a writer after
+// it finishes mutating the guarded data, passing the value returned by
+// the matching WriterCriticalSectionEnter call.
+func (p *WriterReaderPhaser) WriterCriticalSectionExit(criticalValueAtEnter int64) {
+	if criticalValueAtEnter < 0 {
+		atomic.AddInt64(&p.oddEndEpoch, 1)
+	} else {
+		atomic.AddInt64(&p.evenEndEpoch, 1)
+	}
+}
+
+// FlipPhase blocks until every writer that entered the current phase
+// before the call has exited, then flips the phase so that subsequent
+// writers are tracked against the other epoch counter. Only one reader
+// may call FlipPhase at a time; concurrent callers serialize on an
+// internal lock.
+func (p *WriterReaderPhaser) FlipPhase() {
+	p.readerMu.Lock()
+	defer p.readerMu.Unlock()
+
+	nextPhaseIsEven := atomic.LoadInt64(&p.startEpoch) < 0
+
+	var initialStartValue int64
+	if nextPhaseIsEven {
+		atomic.StoreInt64(&p.evenEndEpoch, 0)
+	} else {
+		initialStartValue = math.MinInt64
+		atomic.StoreInt64(&p.oddEndEpoch, initialStartValue)
+	}
+
+	startValueAtFlip := atomic.SwapInt64(&p.startEpoch, initialStartValue)
+
+	for {
+		var caughtUp bool
+		if nextPhaseIsEven {
+			caughtUp = atomic.LoadInt64(&p.oddEndEpoch) == startValueAtFlip
+		} else {
+			caughtUp = atomic.LoadInt64(&p.evenEndEpoch) == startValueAtFlip
+		}
+		if caughtUp {
+			return
+		}
+		runtime.Gosched()
+	}
+}
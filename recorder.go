@@ -0,0 +1,81 @@
+package hdrhistogram
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// A Recorder lets many goroutines record values concurrently into an
+// "active" Histogram while a single goroutine periodically calls Sample
+// (or SampleInto) to obtain a stable snapshot of everything recorded
+// since the previous sample. Sample swaps in the idle histogram, waits
+// for writers still in flight against the old active histogram to
+// drain, and merges the drained histogram into a newly allocated result
+// before resetting it for reuse as the next idle histogram.
+type Recorder struct {
+	phaser *WriterReaderPhaser
+	active atomic.Pointer[Histogram]
+	idle   *Histogram
+}
+
+// NewRecorder returns a Recorder whose histograms track values in the
+// given range and with the given amount of precision, as with New.
+func NewRecorder(minValue, maxValue int64, sigfigs int) *Recorder {
+	r := &Recorder{
+		phaser: NewWriterReaderPhaser(),
+		idle:   New(minValue, maxValue, sigfigs),
+	}
+	r.active.Store(New(minValue, maxValue, sigfigs))
+	return r
+}
+
+// RecordValue records v against the active histogram. Safe to call
+// concurrently from many goroutines, including while Sample is running.
+func (r *Recorder) RecordValue(v int64) error {
+	return r.RecordValues(v, 1)
+}
+
+// RecordValues records n occurrences of v against the active histogram.
+// Safe to call concurrently from many goroutines, including while Sample
+// is running.
+func (r *Recorder) RecordValues(v, n int64) error {
+	crit := r.phaser.WriterCriticalSectionEnter()
+	defer r.phaser.WriterCriticalSectionExit(crit)
+	return r.active.Load().recordValuesAtomic(v, n)
+}
+
+// recordValuesAtomic behaves like RecordValues but updates counts and
+// totalCount atomically, since a Recorder's active histogram is written
+// to by many goroutines at once; the phaser only guarantees writers have
+// drained before the reader reuses a histogram, not mutual exclusion
+// between writers.
+func (h *Histogram) recordValuesAtomic(v, n int64) error {
+	idx := h.countsIndexFor(v)
+	if idx < 0 || int(h.countsLen) <= idx {
+		return fmt.Errorf("value %d is too large to be recorded", v)
+	}
+	atomic.AddInt64(&h.counts[idx], n)
+	atomic.AddInt64(&h.totalCount, n)
+	return nil
+}
+
+// Sample returns a new Histogram holding everything recorded since the
+// previous call to Sample (or since the Recorder was created).
+func (r *Recorder) Sample() *Histogram {
+	snapshot := New(r.idle.lowestTrackableValue, r.idle.highestTrackableValue, int(r.idle.significantFigures))
+	r.SampleInto(snapshot)
+	return snapshot
+}
+
+// SampleInto behaves like Sample but merges the interval into dst
+// instead of allocating a new Histogram, resetting dst first.
+func (r *Recorder) SampleInto(dst *Histogram) {
+	dst.Reset()
+
+	drained := r.active.Swap(r.idle)
+	r.phaser.FlipPhase()
+
+	dst.Merge(drained)
+	drained.Reset()
+	r.idle = drained
+}
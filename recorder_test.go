@@ -0,0 +1,84 @@
+package hdrhistogram
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRecorderSample(t *testing.T) {
+	r := NewRecorder(1, 3600000000, 3)
+	for i := int64(0); i < 100; i++ {
+		if err := r.RecordValue(i + 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snap := r.Sample()
+	if snap.totalCount != 100 {
+		t.Fatalf("totalCount mismatch: got %d want 100", snap.totalCount)
+	}
+
+	if err := r.RecordValue(5); err != nil {
+		t.Fatal(err)
+	}
+	snap2 := r.Sample()
+	if snap2.totalCount != 1 {
+		t.Fatalf("second interval totalCount mismatch: got %d want 1", snap2.totalCount)
+	}
+}
+
+func TestRecorderSampleInto(t *testing.T) {
+	r := NewRecorder(1, 3600000000, 3)
+	r.RecordValue(42)
+
+	dst := New(1, 3600000000, 3)
+	dst.RecordValue(999) // should be wiped by SampleInto's Reset
+
+	r.SampleInto(dst)
+	if dst.totalCount != 1 {
+		t.Fatalf("totalCount mismatch: got %d want 1", dst.totalCount)
+	}
+}
+
+func TestRecorderConcurrentRecordValue(t *testing.T) {
+	r := NewRecorder(1, 3600000000, 3)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int64(1); i <= 1000; i++ {
+				if err := r.RecordValue(i); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := r.Sample().totalCount; got != 8000 {
+		t.Fatalf("totalCount mismatch: got %d want 8000", got)
+	}
+}
+
+func TestWriterReaderPhaserFlipWaitsForWriters(t *testing.T) {
+	p := NewWriterReaderPhaser()
+
+	crit := p.WriterCriticalSectionEnter()
+
+	done := make(chan struct{})
+	go func() {
+		p.FlipPhase()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("FlipPhase returned before the in-flight writer exited")
+	default:
+	}
+
+	p.WriterCriticalSectionExit(crit)
+	<-done
+}
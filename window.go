@@ -0,0 +1,112 @@
+package hdrhistogram
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A WindowedHistogram keeps a fixed number of rotating Histogram chunks
+// covering a configurable duration (e.g. 5 minutes of 5-second chunks)
+// and exposes Merged to query only the recent window instead of the
+// full lifetime of the process.
+type WindowedHistogram struct {
+	mu                 sync.Mutex
+	chunks             []*Histogram
+	current            int
+	minValue, maxValue int64
+	sigfigs            int
+
+	stop chan struct{}
+}
+
+// NewWindowedHistogram returns a WindowedHistogram with numChunks
+// rotating chunks, each tracking values in the given range and with the
+// given amount of precision, as with New.
+func NewWindowedHistogram(numChunks int, minValue, maxValue int64, sigfigs int) *WindowedHistogram {
+	if numChunks < 1 {
+		panic(fmt.Errorf("numChunks must be >= 1 (was %d)", numChunks))
+	}
+
+	chunks := make([]*Histogram, numChunks)
+	for i := range chunks {
+		chunks[i] = New(minValue, maxValue, sigfigs)
+	}
+
+	return &WindowedHistogram{
+		chunks:   chunks,
+		minValue: minValue,
+		maxValue: maxValue,
+		sigfigs:  sigfigs,
+	}
+}
+
+// RecordValue records v against the current chunk.
+func (w *WindowedHistogram) RecordValue(v int64) error {
+	return w.RecordValues(v, 1)
+}
+
+// RecordValues records n occurrences of v against the current chunk.
+func (w *WindowedHistogram) RecordValues(v, n int64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.chunks[w.current].RecordValues(v, n)
+}
+
+// Rotate advances to the next chunk, resetting it so it can start
+// accumulating the newest interval and dropping the oldest interval from
+// the window.
+func (w *WindowedHistogram) Rotate() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.current = (w.current + 1) % len(w.chunks)
+	w.chunks[w.current].Reset()
+}
+
+// Merged returns a new Histogram combining every chunk currently in the
+// window.
+func (w *WindowedHistogram) Merged() *Histogram {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	merged := New(w.minValue, w.maxValue, w.sigfigs)
+	for _, c := range w.chunks {
+		merged.Merge(c)
+	}
+	return merged
+}
+
+// AutoRotate starts a goroutine that calls Rotate every interval until
+// Stop is called. It is optional: callers that want full control over
+// rotation timing can just call Rotate themselves.
+func (w *WindowedHistogram) AutoRotate(interval time.Duration) {
+	w.mu.Lock()
+	stop := make(chan struct{})
+	w.stop = stop
+	w.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.Rotate()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the goroutine started by AutoRotate. It is a no-op if
+// AutoRotate was never called or Stop was already called.
+func (w *WindowedHistogram) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stop == nil {
+		return
+	}
+	close(w.stop)
+	w.stop = nil
+}
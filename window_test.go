@@ -0,0 +1,62 @@
+package hdrhistogram
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWindowedHistogramRotate(t *testing.T) {
+	w := NewWindowedHistogram(3, 1, 3600000000, 3)
+	w.RecordValue(10)
+	w.Rotate()
+	w.RecordValue(20)
+	w.Rotate()
+	w.RecordValue(30)
+
+	if got := w.Merged().totalCount; got != 3 {
+		t.Fatalf("totalCount mismatch: got %d want 3", got)
+	}
+
+	w.Rotate() // drops the chunk holding value 10
+	if got := w.Merged().totalCount; got != 2 {
+		t.Fatalf("totalCount mismatch after drop: got %d want 2", got)
+	}
+}
+
+func TestWindowedHistogramAutoRotate(t *testing.T) {
+	w := NewWindowedHistogram(2, 1, 3600000000, 3)
+	w.RecordValue(42)
+	w.AutoRotate(5 * time.Millisecond)
+	defer w.Stop()
+
+	// Enough rotations to cycle the marker value out of every chunk.
+	time.Sleep(50 * time.Millisecond)
+
+	if got := w.Merged().totalCount; got != 0 {
+		t.Fatalf("expected AutoRotate to have rotated the marker value out, got totalCount %d", got)
+	}
+}
+
+func TestWindowedHistogramStopIsIdempotent(t *testing.T) {
+	w := NewWindowedHistogram(2, 1, 3600000000, 3)
+	w.AutoRotate(time.Hour)
+
+	w.Stop()
+	w.Stop() // must not panic
+}
+
+func TestWindowedHistogramConcurrentAutoRotate(t *testing.T) {
+	w := NewWindowedHistogram(2, 1, 3600000000, 3)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.AutoRotate(time.Millisecond)
+			w.Stop()
+		}()
+	}
+	wg.Wait()
+}